@@ -0,0 +1,88 @@
+package logrusgce
+
+import (
+	"fmt"
+	"runtime"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// errorReportingType is the @type Cloud Error Reporting looks for to
+// recognize a structured log entry as an error event:
+// https://cloud.google.com/error-reporting/docs/formatting-error-messages
+const errorReportingType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+// stackTracer is implemented by github.com/pkg/errors-style errors that
+// carry their own captured stack trace.
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// formatErrorReport rewrites data in place so the entry is picked up by
+// Google Cloud Error Reporting: it sets @type, appends a newline and a stack
+// trace to message (preferring one carried by origErr, if any), and
+// populates context.reportLocation from loc.
+//
+// This clobbers any user field named "context", the same way the
+// logging.googleapis.com/* fields reserve their own names when set; unlike
+// those, "context" isn't namespaced, so a caller logging a field with that
+// name will see it silently overwritten when ErrorReporting is on.
+func formatErrorReport(data map[string]interface{}, message string, origErr error, loc sourceLocation) {
+	data["@type"] = errorReportingType
+	data["message"] = message + "\n" + stackTraceFor(origErr)
+	data["context"] = map[string]interface{}{
+		"reportLocation": loc,
+	}
+}
+
+func stackTraceFor(origErr error) string {
+	if st, ok := origErr.(stackTracer); ok {
+		return fmt.Sprintf("%+v", st.StackTrace())
+	}
+	return captureStack()
+}
+
+// captureStackHelpers are this package's own frames between captureStack
+// and the GCEFormatter.Format call that reaches it, named explicitly
+// (rather than matched by package prefix) so captureStack only skips past
+// its own plumbing, not arbitrary caller code that happens to live in this
+// package, such as this package's own tests.
+var captureStackHelpers = map[string]bool{
+	"github.com/marcelhuth/logrus-gce.captureStack":           true,
+	"github.com/marcelhuth/logrus-gce.stackTraceFor":          true,
+	"github.com/marcelhuth/logrus-gce.formatErrorReport":      true,
+	"github.com/marcelhuth/logrus-gce.(*GCEFormatter).Format": true,
+}
+
+// captureStack renders a Go-style stack trace starting at the first frame
+// above captureStack that belongs to neither this package's own plumbing
+// nor logrus, so the trace starts at the caller's own code rather than at
+// whichever helper or logrus Entry method happened to still have a frame
+// on the stack. It walks runtime.CallersFrames itself instead of accepting
+// a precomputed skip count: a skip tuned for one call depth (e.g.
+// GCEFormatter calling runtime.Caller directly) doesn't carry over to this
+// deeper call chain, and logrus's one-line level methods are small enough
+// to be inlined away unpredictably depending on the entry point used.
+func captureStack() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var trace string
+	skipping := true
+	for {
+		frame, more := frames.Next()
+		if skipping && (isLogrusFrame(frame.Function) || captureStackHelpers[frame.Function]) {
+			if !more {
+				break
+			}
+			continue
+		}
+		skipping = false
+		trace += fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return trace
+}
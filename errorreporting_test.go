@@ -0,0 +1,51 @@
+package logrusgce
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logAnError exists so the test has a stable, named caller frame to assert
+// against: reportLocation and the stack trace's first frame should point
+// here, not at logrus internals.
+func logAnError(logger *logrus.Logger) {
+	logger.WithField("error", fmt.Errorf("boom")).Error("something failed")
+}
+
+func TestFormatErrorReportPointsAtCallerNotLogrus(t *testing.T) {
+	var buf strings.Builder
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.SetFormatter(NewGCEFormatterWithOptions(GCEFormatterOptions{ErrorReporting: true}))
+
+	logAnError(logger)
+
+	var entry struct {
+		Context struct {
+			ReportLocation struct {
+				Function string `json:"function"`
+			} `json:"reportLocation"`
+		} `json:"context"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	const wantFunction = "github.com/marcelhuth/logrus-gce.logAnError"
+	if entry.Context.ReportLocation.Function != wantFunction {
+		t.Fatalf("reportLocation.function = %q, want %q", entry.Context.ReportLocation.Function, wantFunction)
+	}
+
+	stack := strings.SplitN(entry.Message, "\n", 2)
+	if len(stack) < 2 || !strings.HasPrefix(stack[1], wantFunction+"\n") {
+		t.Fatalf("message stack = %q, want it to start with %q", entry.Message, wantFunction)
+	}
+	if strings.Contains(entry.Message, "sirupsen/logrus") {
+		t.Fatalf("message stack contains logrus internals: %q", entry.Message)
+	}
+}
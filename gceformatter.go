@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"runtime"
 	"strings"
 	"sync"
@@ -50,6 +51,15 @@ var (
 	ErrSkipNotFound = errors.New("could not find skips for log level")
 )
 
+// logrusFuncPrefix matches the import path logrus frame names start with;
+// isLogrusFrame is shared by getSkipLevel and captureStack so both walk past
+// the same set of frames when looking for the caller's own call site.
+const logrusFuncPrefix = "github.com/sirupsen/logrus."
+
+func isLogrusFrame(functionName string) bool {
+	return strings.HasPrefix(functionName, logrusFuncPrefix)
+}
+
 type sourceLocation struct {
 	File string `json:"file"`
 	Line int    `json:"line"`
@@ -73,11 +83,22 @@ func getSkipLevel(level logrus.Level) (int, error) {
 
 	// detect until we escape logrus back to the client package
 	// skip out of runtime and logrusgce package, hence 3
-	stackSkipsCallers := make([]uintptr, 20)
-	runtime.Callers(3, stackSkipsCallers)
-	for i, pc := range stackSkipsCallers {
-		f := runtime.FuncForPC(pc)
-		if strings.HasPrefix(f.Name(), "github.com/sirupsen/logrus") {
+	//
+	// Walked via CallersFrames rather than indexing pcs directly: logrus's
+	// one-line Error/Warn/... wrappers are small enough to be inlined by the
+	// compiler, and runtime.FuncForPC resolves an inlined call's PC to its
+	// enclosing (non-logrus) function, silently hiding the wrapper frame
+	// from a raw pc/FuncForPC walk and throwing off the count by however
+	// many wrappers got inlined away.
+	pcs := make([]uintptr, 20)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if isLogrusFrame(frame.Function) {
+			if !more {
+				break
+			}
 			continue
 		}
 		stackSkips[level] = i + 1
@@ -88,13 +109,55 @@ func getSkipLevel(level logrus.Level) (int, error) {
 
 type GCEFormatter struct {
 	withSourceInfo bool
+
+	// ProjectID is the GCP project the logs are shipped to. When set, it is
+	// used to expand trace IDs into the fully-qualified
+	// "projects/<ProjectID>/traces/<traceID>" form Cloud Logging expects for
+	// the logging.googleapis.com/trace field.
+	ProjectID string
+
+	// ErrorReporting, when true, formats ErrorLevel and above entries so
+	// Google Cloud Error Reporting picks them up: it sets @type, appends a
+	// newline and a stack trace to message, and populates
+	// context.reportLocation.
+	// This reserves the "context" field, overwriting any user-supplied
+	// field of that name.
+	ErrorReporting bool
+
+	// Redactors run, in order, over every value in entry.Data (recursing
+	// into nested map[string]interface{} and []interface{} values, see
+	// redactValue) before it is marshaled to JSON.
+	Redactors []Redactor
 }
 
 func NewGCEFormatter(withSourceInfo bool) *GCEFormatter {
 	return &GCEFormatter{withSourceInfo: withSourceInfo}
 }
 
+// GCEFormatterOptions configures a GCEFormatter created via
+// NewGCEFormatterWithOptions.
+type GCEFormatterOptions struct {
+	WithSourceInfo bool
+	ProjectID      string
+	ErrorReporting bool
+	Redactors      []Redactor
+}
+
+// NewGCEFormatterWithOptions is like NewGCEFormatter but allows setting
+// additional options such as ProjectID, which is required for Cloud Trace
+// correlation.
+func NewGCEFormatterWithOptions(opts GCEFormatterOptions) *GCEFormatter {
+	return &GCEFormatter{
+		withSourceInfo: opts.WithSourceInfo,
+		ProjectID:      opts.ProjectID,
+		ErrorReporting: opts.ErrorReporting,
+		Redactors:      opts.Redactors,
+	}
+}
+
 func (f *GCEFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	origErr, _ := entry.Data["error"].(error)
+
 	data := make(logrus.Fields, len(entry.Data)+3)
 	for k, v := range entry.Data {
 		switch v := v.(type) {
@@ -107,22 +170,75 @@ func (f *GCEFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		}
 	}
 
+	if len(f.Redactors) > 0 {
+		for k, v := range data {
+			data[k] = redactValue(f.Redactors, k, v)
+		}
+	}
+
 	data["time"] = entry.Time.Format(time.RFC3339Nano)
 	data["severity"] = levelsLogrusToGCE[entry.Level]
 	data["message"] = entry.Message
 
-	if f.withSourceInfo {
+	if v, present := data[fieldHTTPRequest]; present {
+		if req, isReq := v.(*http.Request); isReq {
+			v = HTTPRequest(req, 0, 0, 0)
+		}
+		// RequestUrl can carry userinfo credentials (e.g. from req.URL), so
+		// it needs a second pass through the redactors: the loop above
+		// already ran before *http.Request was converted to HTTPRequestInfo.
+		if info, isInfo := v.(HTTPRequestInfo); isInfo && len(f.Redactors) > 0 {
+			if redacted, ok := redactValue(f.Redactors, "requestUrl", info.RequestUrl).(string); ok {
+				info.RequestUrl = redacted
+			}
+			v = info
+		}
+		data[fieldHTTPRequest] = v
+	}
+
+	if v, present := data[fieldLabels]; present {
+		data["logging.googleapis.com/labels"] = v
+		delete(data, fieldLabels)
+	}
+	if v, present := data[fieldOperation]; present {
+		data["logging.googleapis.com/operation"] = v
+		delete(data, fieldOperation)
+	}
+	if v, present := data[fieldInsertID]; present {
+		data["logging.googleapis.com/insertId"] = v
+		delete(data, fieldInsertID)
+	}
+
+	if traceID, spanID, sampled, ok := extractTrace(f.ProjectID, data, entry.Context); ok {
+		if traceID != "" {
+			data["logging.googleapis.com/trace"] = traceID
+		}
+		if spanID != "" {
+			data["logging.googleapis.com/spanId"] = spanID
+		}
+		data["logging.googleapis.com/trace_sampled"] = sampled
+	}
+
+	if f.withSourceInfo || (f.ErrorReporting && entry.Level <= logrus.ErrorLevel) {
 		skip, err := getSkipLevel(entry.Level)
 		if err != nil {
 			return nil, err
 		}
 		if pc, file, line, ok := runtime.Caller(skip); ok {
-			f := runtime.FuncForPC(pc)
-			data["logging.googleapis.com/sourceLocation"] = map[string]interface{}{
-				"file": file,
-				"line": line,
-				// FunctionName is "function" in JSON: https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry?hl=de#logentrysourcelocation
-				"function": f.Name(),
+			fn := runtime.FuncForPC(pc)
+			loc := sourceLocation{File: file, Line: line, FunctionName: fn.Name()}
+
+			if f.withSourceInfo {
+				data["logging.googleapis.com/sourceLocation"] = map[string]interface{}{
+					"file": loc.File,
+					"line": loc.Line,
+					// FunctionName is "function" in JSON: https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry?hl=de#logentrysourcelocation
+					"function": loc.FunctionName,
+				}
+			}
+
+			if f.ErrorReporting && entry.Level <= logrus.ErrorLevel {
+				formatErrorReport(data, entry.Message, origErr, loc)
 			}
 		}
 	}
@@ -0,0 +1,200 @@
+package logrusgce
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+
+	"cloud.google.com/go/logging"
+	logpb "cloud.google.com/go/logging/apiv2/loggingpb"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fieldLabels is the logrus field promoted to logging.Entry.Labels / the
+// logging.googleapis.com/labels LogEntry field.
+const fieldLabels = "labels"
+
+// levelsLogrusToLoggingSeverity mirrors levelsLogrusToGCE for the
+// cloud.google.com/go/logging API, which uses its own Severity type rather
+// than the string values GCEFormatter writes.
+var levelsLogrusToLoggingSeverity = map[logrus.Level]logging.Severity{
+	logrus.DebugLevel: logging.Debug,
+	logrus.InfoLevel:  logging.Info,
+	logrus.WarnLevel:  logging.Warning,
+	logrus.ErrorLevel: logging.Error,
+	logrus.FatalLevel: logging.Critical,
+	logrus.PanicLevel: logging.Alert,
+}
+
+// Hook ships logrus entries straight to Cloud Logging via the
+// cloud.google.com/go/logging client, instead of relying on the structured
+// stdout + Fluent Bit agent pipeline GCEFormatter targets. Use it when
+// running outside GKE/Cloud Run, where that auto-ingestion isn't available.
+//
+// Hook relies on logging.Logger's own async buffering, so Fire never blocks
+// on the network. The Cloud Logging API doesn't report which buffered
+// entry a shipping error belongs to, so on any such error Hook falls back
+// to writing a formatted notice (via Fallback) to FallbackWriter rather
+// than silently dropping entries.
+type Hook struct {
+	client *logging.Client
+	logger *logging.Logger
+
+	// Fallback formats the notice written to FallbackWriter when Cloud
+	// Logging reports a shipping error. Defaults to a GCEFormatter with
+	// source info enabled.
+	Fallback *GCEFormatter
+	// FallbackWriter receives Fallback-formatted notices. Defaults to
+	// os.Stderr.
+	FallbackWriter io.Writer
+
+	// ProjectID is used, as in GCEFormatter, to expand trace IDs into the
+	// "projects/<ProjectID>/traces/<traceID>" form Cloud Logging expects.
+	ProjectID string
+
+	// Redactors run, in order, over every value in entry.Data (recursing
+	// into nested map[string]interface{} and []interface{} values, as
+	// GCEFormatter.Redactors do) before it is sent to Cloud Logging. Unlike
+	// GCEFormatter, Hook does not pass the request URL of an httpRequest
+	// field through them: it is sent as that field carries it.
+	Redactors []Redactor
+}
+
+// NewHook creates a Hook that writes to the Cloud Logging log named logID
+// through client. Call Close when done to flush buffered entries and
+// release the client.
+func NewHook(client *logging.Client, logID string, projectID string) *Hook {
+	h := &Hook{
+		client:         client,
+		Fallback:       NewGCEFormatter(true),
+		FallbackWriter: os.Stderr,
+		ProjectID:      projectID,
+	}
+	client.OnError = h.onError
+	h.logger = client.Logger(logID)
+	return h
+}
+
+func (h *Hook) onError(err error) {
+	h.writeFallback(&logrus.Entry{
+		Level:   logrus.ErrorLevel,
+		Message: "logrusgce: Cloud Logging reported a shipping error, some buffered entries may have been dropped: " + err.Error(),
+		Data:    logrus.Fields{},
+	})
+}
+
+func (h *Hook) writeFallback(entry *logrus.Entry) {
+	serialized, err := h.Fallback.Format(entry)
+	if err != nil {
+		return
+	}
+	_, _ = h.FallbackWriter.Write(serialized)
+}
+
+// Levels implements logrus.Hook.
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	h.logger.Log(h.toLoggingEntry(entry))
+	return nil
+}
+
+func (h *Hook) toLoggingEntry(entry *logrus.Entry) logging.Entry {
+	payload := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		switch v := v.(type) {
+		case error:
+			payload[k] = v.Error()
+		default:
+			payload[k] = v
+		}
+	}
+	if len(h.Redactors) > 0 {
+		for k, v := range payload {
+			payload[k] = redactValue(h.Redactors, k, v)
+		}
+	}
+
+	payload["message"] = entry.Message
+
+	logEntry := logging.Entry{
+		Timestamp: entry.Time,
+		Severity:  levelsLogrusToLoggingSeverity[entry.Level],
+	}
+
+	if v, present := payload[fieldHTTPRequest]; present {
+		if req, ok := toLoggingHTTPRequest(v); ok {
+			logEntry.HTTPRequest = req
+			delete(payload, fieldHTTPRequest)
+		}
+	}
+
+	if labels, ok := payload[fieldLabels].(map[string]string); ok {
+		logEntry.Labels = labels
+		delete(payload, fieldLabels)
+	}
+
+	if traceID, spanID, sampled, ok := extractTrace(h.ProjectID, payload, entry.Context); ok {
+		logEntry.Trace = traceID
+		logEntry.SpanID = spanID
+		logEntry.TraceSampled = sampled
+	}
+
+	if skip, err := getSkipLevel(entry.Level); err == nil {
+		if pc, file, line, ok := runtime.Caller(skip); ok {
+			fn := runtime.FuncForPC(pc)
+			logEntry.SourceLocation = &logpb.LogEntrySourceLocation{
+				File:     file,
+				Line:     int64(line),
+				Function: fn.Name(),
+			}
+		}
+	}
+
+	logEntry.Payload = payload
+	return logEntry
+}
+
+// toLoggingHTTPRequest adapts the value carried in the "httpRequest" field
+// to *logging.HTTPRequest. It accepts a *logging.HTTPRequest directly, or,
+// as GCEFormatter does, a bare *http.Request or the HTTPRequestInfo built
+// by the HTTPRequest helper (httprequest.go).
+// logging.HTTPRequest requires a non-nil *http.Request, so an
+// HTTPRequestInfo built without one (e.g. decoded from JSON) can't be
+// adapted and is left in Payload instead.
+func toLoggingHTTPRequest(v interface{}) (*logging.HTTPRequest, bool) {
+	switch req := v.(type) {
+	case *logging.HTTPRequest:
+		return req, true
+	case *http.Request:
+		return &logging.HTTPRequest{Request: req}, true
+	case HTTPRequestInfo:
+		if req.request == nil {
+			return nil, false
+		}
+		return &logging.HTTPRequest{
+			Request:      req.request,
+			RequestSize:  int64(req.RequestSize),
+			Status:       req.Status,
+			ResponseSize: int64(req.ResponseSize),
+			Latency:      req.latency,
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// Flush blocks until all buffered entries have been sent to Cloud Logging.
+func (h *Hook) Flush() error {
+	return h.logger.Flush()
+}
+
+// Close flushes all of the client's loggers and releases its connection.
+func (h *Hook) Close() error {
+	return h.client.Close()
+}
@@ -0,0 +1,194 @@
+package logrusgce
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/sirupsen/logrus"
+)
+
+func TestToLoggingHTTPRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/path", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest: %v", err)
+	}
+
+	t.Run("passes through *logging.HTTPRequest", func(t *testing.T) {
+		want := &logging.HTTPRequest{Request: req, Status: 200}
+		got, ok := toLoggingHTTPRequest(want)
+		if !ok || got != want {
+			t.Fatalf("got = %v, %v, want %v, true", got, ok, want)
+		}
+	})
+
+	t.Run("wraps a bare *http.Request", func(t *testing.T) {
+		got, ok := toLoggingHTTPRequest(req)
+		if !ok {
+			t.Fatal("ok = false, want true")
+		}
+		if got.Request != req {
+			t.Fatalf("Request = %v, want %v", got.Request, req)
+		}
+	})
+
+	t.Run("adapts HTTPRequestInfo built by the HTTPRequest helper", func(t *testing.T) {
+		info := HTTPRequest(req, 201, 250*time.Millisecond, 1024)
+		got, ok := toLoggingHTTPRequest(info)
+		if !ok {
+			t.Fatal("ok = false, want true")
+		}
+		if got.Request != req {
+			t.Fatalf("Request = %v, want %v", got.Request, req)
+		}
+		if got.Status != 201 {
+			t.Fatalf("Status = %d, want 201", got.Status)
+		}
+		if got.ResponseSize != 1024 {
+			t.Fatalf("ResponseSize = %d, want 1024", got.ResponseSize)
+		}
+		if got.Latency != 250*time.Millisecond {
+			t.Fatalf("Latency = %v, want 250ms", got.Latency)
+		}
+	})
+
+	t.Run("HTTPRequestInfo without a retained request can't be adapted", func(t *testing.T) {
+		info := HTTPRequestInfo{Status: 200}
+		_, ok := toLoggingHTTPRequest(info)
+		if ok {
+			t.Fatal("ok = true, want false: logging.HTTPRequest requires a non-nil Request")
+		}
+	})
+
+	t.Run("unrecognized types are left for the caller", func(t *testing.T) {
+		_, ok := toLoggingHTTPRequest("not a request")
+		if ok {
+			t.Fatal("ok = true, want false")
+		}
+	})
+}
+
+func TestHookToLoggingEntry(t *testing.T) {
+	t.Run("maps severity and builds the payload", func(t *testing.T) {
+		h := &Hook{}
+		entry := &logrus.Entry{
+			Level:   logrus.WarnLevel,
+			Message: "something happened",
+			Data:    logrus.Fields{"user": "alice"},
+		}
+
+		got := h.toLoggingEntry(entry)
+
+		if got.Severity != logging.Warning {
+			t.Fatalf("Severity = %v, want %v", got.Severity, logging.Warning)
+		}
+		payload, ok := got.Payload.(logrus.Fields)
+		if !ok {
+			t.Fatalf("Payload = %#v, want logrus.Fields", got.Payload)
+		}
+		if payload["message"] != "something happened" {
+			t.Fatalf("payload[message] = %v, want %q", payload["message"], "something happened")
+		}
+		if payload["user"] != "alice" {
+			t.Fatalf("payload[user] = %v, want %q", payload["user"], "alice")
+		}
+	})
+
+	t.Run("errors are stringified in the payload", func(t *testing.T) {
+		err := errors.New("boom")
+		h := &Hook{}
+		entry := &logrus.Entry{Data: logrus.Fields{"error": err}}
+
+		got := h.toLoggingEntry(entry)
+
+		payload := got.Payload.(logrus.Fields)
+		if payload["error"] != err.Error() {
+			t.Fatalf("payload[error] = %v, want %q", payload["error"], err.Error())
+		}
+	})
+
+	t.Run("labels field is hoisted to Labels", func(t *testing.T) {
+		h := &Hook{}
+		entry := &logrus.Entry{Data: logrus.Fields{fieldLabels: map[string]string{"env": "prod"}}}
+
+		got := h.toLoggingEntry(entry)
+
+		if got.Labels["env"] != "prod" {
+			t.Fatalf("Labels = %v, want env=prod", got.Labels)
+		}
+		payload := got.Payload.(logrus.Fields)
+		if _, present := payload[fieldLabels]; present {
+			t.Fatalf("payload = %v, want %q removed", payload, fieldLabels)
+		}
+	})
+
+	t.Run("trace fields are extracted using ProjectID", func(t *testing.T) {
+		h := &Hook{ProjectID: "my-project"}
+		entry := &logrus.Entry{Data: logrus.Fields{"trace_id": "abc123", "span_id": "def456"}}
+
+		got := h.toLoggingEntry(entry)
+
+		if want := "projects/my-project/traces/abc123"; got.Trace != want {
+			t.Fatalf("Trace = %q, want %q", got.Trace, want)
+		}
+		if got.SpanID != "def456" {
+			t.Fatalf("SpanID = %q, want %q", got.SpanID, "def456")
+		}
+	})
+
+	t.Run("httpRequest field is adapted and removed from the payload", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://example.com/path", nil)
+		if err != nil {
+			t.Fatalf("http.NewRequest: %v", err)
+		}
+		h := &Hook{}
+		entry := &logrus.Entry{Data: logrus.Fields{fieldHTTPRequest: req}}
+
+		got := h.toLoggingEntry(entry)
+
+		if got.HTTPRequest == nil || got.HTTPRequest.Request != req {
+			t.Fatalf("HTTPRequest = %v, want it wrapping %v", got.HTTPRequest, req)
+		}
+		payload := got.Payload.(logrus.Fields)
+		if _, present := payload[fieldHTTPRequest]; present {
+			t.Fatalf("payload = %v, want %q removed", payload, fieldHTTPRequest)
+		}
+	})
+
+	t.Run("sourceLocation is populated", func(t *testing.T) {
+		h := &Hook{}
+		entry := &logrus.Entry{Data: logrus.Fields{}}
+
+		got := h.toLoggingEntry(entry)
+
+		if got.SourceLocation == nil {
+			t.Fatal("SourceLocation = nil, want it populated")
+		}
+	})
+
+	t.Run("Redactors scrub payload values before shipping", func(t *testing.T) {
+		h := &Hook{Redactors: []Redactor{NewKeyRedactor("password")}}
+		entry := &logrus.Entry{Data: logrus.Fields{"password": "hunter2"}}
+
+		got := h.toLoggingEntry(entry)
+
+		payload := got.Payload.(logrus.Fields)
+		if payload["password"] != redactedPlaceholder {
+			t.Fatalf("payload[password] = %v, want %q", payload["password"], redactedPlaceholder)
+		}
+	})
+
+	t.Run("without Redactors, payload values pass through unredacted", func(t *testing.T) {
+		h := &Hook{}
+		entry := &logrus.Entry{Data: logrus.Fields{"password": "hunter2"}}
+
+		got := h.toLoggingEntry(entry)
+
+		payload := got.Payload.(logrus.Fields)
+		if payload["password"] != "hunter2" {
+			t.Fatalf("payload[password] = %v, want it left untouched: %q", payload["password"], "hunter2")
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package logrusgce
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const fieldHTTPRequest = "httpRequest"
+
+// byteSize is an int64 that marshals as a JSON string, matching the
+// proto3 int64-to-string convention the LogEntry.HttpRequest proto uses
+// for RequestSize/ResponseSize: https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#HttpRequest
+// A plain int64 field would marshal as a raw JSON number and fail to parse
+// as the documented type when ingested.
+type byteSize int64
+
+func (b byteSize) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, strconv.FormatInt(int64(b), 10)), nil
+}
+
+// HTTPRequestInfo is the Cloud Logging LogEntry httpRequest shape:
+// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#HttpRequest
+type HTTPRequestInfo struct {
+	RequestMethod string   `json:"requestMethod,omitempty"`
+	RequestUrl    string   `json:"requestUrl,omitempty"`
+	RequestSize   byteSize `json:"requestSize,omitempty"`
+	Status        int      `json:"status,omitempty"`
+	ResponseSize  byteSize `json:"responseSize,omitempty"`
+	UserAgent     string   `json:"userAgent,omitempty"`
+	RemoteIp      string   `json:"remoteIp,omitempty"`
+	ServerIp      string   `json:"serverIp,omitempty"`
+	Referer       string   `json:"referer,omitempty"`
+	Latency       string   `json:"latency,omitempty"`
+	Protocol      string   `json:"protocol,omitempty"`
+
+	// request and latency retain the inputs HTTPRequest was built from, so
+	// Hook can adapt this value to *logging.HTTPRequest without having to
+	// re-parse Latency or reconstruct the request. Unexported: excluded
+	// from the JSON GCEFormatter writes.
+	request *http.Request
+	latency time.Duration
+}
+
+// HTTPRequest builds an HTTPRequestInfo from an *http.Request and the
+// outcome of serving it, for use with logrus.WithField("httpRequest", ...).
+func HTTPRequest(req *http.Request, status int, latency time.Duration, respSize int64) HTTPRequestInfo {
+	info := HTTPRequestInfo{
+		Status:       status,
+		ResponseSize: byteSize(respSize),
+		Latency:      formatLatency(latency),
+		request:      req,
+		latency:      latency,
+	}
+	if req != nil {
+		info.RequestMethod = req.Method
+		info.RequestUrl = req.URL.String()
+		info.RequestSize = byteSize(req.ContentLength)
+		info.UserAgent = req.UserAgent()
+		info.RemoteIp = req.RemoteAddr
+		info.Referer = req.Referer()
+		info.Protocol = req.Proto
+	}
+	return info
+}
+
+// formatLatency renders d in the "<seconds>s" form Cloud Logging expects for
+// httpRequest.latency, e.g. "1.234s". strconv.FormatFloat is used instead of
+// fmt's %g verb, which switches to scientific notation (e.g. "1e-07s") for
+// very small or large durations.
+func formatLatency(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
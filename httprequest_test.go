@@ -0,0 +1,35 @@
+package logrusgce
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPRequestMarshalsSizesAsStrings(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/path", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest: %v", err)
+	}
+	req.ContentLength = 256
+
+	info := HTTPRequest(req, 200, 250*time.Millisecond, 1024)
+
+	b, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	if got, want := decoded["requestSize"], "256"; got != want {
+		t.Fatalf("requestSize = %v (%T), want %q", got, got, want)
+	}
+	if got, want := decoded["responseSize"], "1024"; got != want {
+		t.Fatalf("responseSize = %v (%T), want %q", got, got, want)
+	}
+}
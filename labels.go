@@ -0,0 +1,39 @@
+package logrusgce
+
+import "github.com/sirupsen/logrus"
+
+const (
+	fieldOperation = "operation"
+	fieldInsertID  = "insertId"
+)
+
+// Operation is the logging.googleapis.com/operation LogEntry shape, used to
+// group multiple log entries produced by a single long-running operation:
+// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntryOperation
+type Operation struct {
+	Id       string `json:"id,omitempty"`
+	Producer string `json:"producer,omitempty"`
+	First    bool   `json:"first,omitempty"`
+	Last     bool   `json:"last,omitempty"`
+}
+
+// WithLabels wraps logrus.WithField with the field key GCEFormatter
+// recognizes for logging.googleapis.com/labels, used for indexed filtering
+// in Cloud Logging.
+func WithLabels(labels map[string]string) *logrus.Entry {
+	return logrus.WithField(fieldLabels, labels)
+}
+
+// WithOperation wraps logrus.WithField with the field key GCEFormatter
+// recognizes for logging.googleapis.com/operation, so multi-entry
+// operations group together in the Cloud Logging UI.
+func WithOperation(op Operation) *logrus.Entry {
+	return logrus.WithField(fieldOperation, op)
+}
+
+// WithInsertID wraps logrus.WithField with the field key GCEFormatter
+// recognizes for logging.googleapis.com/insertId, used by Cloud Logging for
+// dedup and ordering guarantees.
+func WithInsertID(id string) *logrus.Entry {
+	return logrus.WithField(fieldInsertID, id)
+}
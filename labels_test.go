@@ -0,0 +1,60 @@
+package logrusgce
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGCEFormatterHoistsLabelsOperationAndInsertID(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     logrus.Fields
+		wantKey  string
+		wantGone string
+	}{
+		{
+			name:     "labels are promoted to logging.googleapis.com/labels",
+			data:     logrus.Fields{fieldLabels: map[string]string{"env": "prod"}},
+			wantKey:  "logging.googleapis.com/labels",
+			wantGone: fieldLabels,
+		},
+		{
+			name:     "operation is promoted to logging.googleapis.com/operation",
+			data:     logrus.Fields{fieldOperation: Operation{Id: "op-1", First: true}},
+			wantKey:  "logging.googleapis.com/operation",
+			wantGone: fieldOperation,
+		},
+		{
+			name:     "insertId is promoted to logging.googleapis.com/insertId",
+			data:     logrus.Fields{fieldInsertID: "abc123"},
+			wantKey:  "logging.googleapis.com/insertId",
+			wantGone: fieldInsertID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &GCEFormatter{}
+			entry := &logrus.Entry{Data: tt.data, Message: "hello"}
+
+			b, err := f.Format(entry)
+			if err != nil {
+				t.Fatalf("Format: %v", err)
+			}
+
+			var decoded map[string]interface{}
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+
+			if _, ok := decoded[tt.wantKey]; !ok {
+				t.Fatalf("decoded = %v, want %q present", decoded, tt.wantKey)
+			}
+			if _, ok := decoded[tt.wantGone]; ok {
+				t.Fatalf("decoded = %v, want %q removed", decoded, tt.wantGone)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package logrusgce
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// redactedPlaceholder replaces values matched by a Redactor.
+const redactedPlaceholder = "xxxxx"
+
+// Redactor scrubs a sensitive value before it reaches Cloud Logging. Redact
+// returns the (possibly modified) value and whether it modified anything.
+type Redactor interface {
+	Redact(key string, value interface{}) (interface{}, bool)
+}
+
+// DefaultSensitiveKeys are the field names KeyRedactor matches when
+// constructed via NewKeyRedactor with no explicit keys.
+var DefaultSensitiveKeys = []string{
+	"password", "authorization", "api_key", "apikey", "set-cookie", "secret", "token",
+}
+
+// KeyRedactor redacts values whose field key matches one of Keys. Keys are
+// matched case-insensitively and may use "*" globs, as supported by
+// path.Match.
+type KeyRedactor struct {
+	Keys []string
+}
+
+// NewKeyRedactor builds a KeyRedactor matching keys, or DefaultSensitiveKeys
+// if none are given.
+func NewKeyRedactor(keys ...string) *KeyRedactor {
+	if len(keys) == 0 {
+		keys = DefaultSensitiveKeys
+	}
+	return &KeyRedactor{Keys: keys}
+}
+
+func (r *KeyRedactor) Redact(key string, value interface{}) (interface{}, bool) {
+	lowerKey := strings.ToLower(key)
+	for _, pattern := range r.Keys {
+		if matched, _ := path.Match(strings.ToLower(pattern), lowerKey); matched {
+			return redactedPlaceholder, true
+		}
+	}
+	return value, false
+}
+
+// URLRedactor strips userinfo passwords and configured query parameters
+// from string values that parse as URLs.
+type URLRedactor struct {
+	QueryParams []string
+}
+
+// NewURLRedactor builds a URLRedactor that additionally strips the given
+// query parameters, beyond always stripping any userinfo password.
+func NewURLRedactor(queryParams ...string) *URLRedactor {
+	return &URLRedactor{QueryParams: queryParams}
+}
+
+func (r *URLRedactor) Redact(_ string, value interface{}) (interface{}, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return value, false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return value, false
+	}
+
+	changed := false
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), redactedPlaceholder)
+			changed = true
+		}
+	}
+	if len(r.QueryParams) > 0 && len(u.RawQuery) > 0 {
+		q := u.Query()
+		for _, param := range r.QueryParams {
+			if q.Get(param) != "" {
+				q.Set(param, redactedPlaceholder)
+				changed = true
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	if !changed {
+		return value, false
+	}
+	return u.String(), true
+}
+
+// DefaultRedactionPatterns are the value patterns RegexpRedactor matches
+// when constructed via NewRegexpRedactor with no explicit patterns.
+var DefaultRedactionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), // JWT
+	regexp.MustCompile(`(?i)bearer\s+[a-z0-9._-]+`),                         // Authorization: Bearer <token>
+	regexp.MustCompile(`\b(?:\d[ -]?){13,16}\b`),                            // credit-card-like
+}
+
+// RegexpRedactor redacts substrings of string values matching any of
+// Patterns.
+type RegexpRedactor struct {
+	Patterns []*regexp.Regexp
+}
+
+// NewRegexpRedactor builds a RegexpRedactor matching patterns, or
+// DefaultRedactionPatterns if none are given.
+func NewRegexpRedactor(patterns ...*regexp.Regexp) *RegexpRedactor {
+	if len(patterns) == 0 {
+		patterns = DefaultRedactionPatterns
+	}
+	return &RegexpRedactor{Patterns: patterns}
+}
+
+func (r *RegexpRedactor) Redact(_ string, value interface{}) (interface{}, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return value, false
+	}
+	changed := false
+	for _, re := range r.Patterns {
+		if re.MatchString(s) {
+			s = re.ReplaceAllString(s, redactedPlaceholder)
+			changed = true
+		}
+	}
+	if !changed {
+		return value, false
+	}
+	return s, true
+}
+
+// redactValue runs value through redactors, recursing into
+// map[string]interface{} and []interface{} values so nested structures of
+// those types are scrubbed as well as top-level fields. Maps and slices of
+// any other type (logrus.Fields, map[string]string, []string, ...) are not
+// descended into; they are passed to the redactors as a single value.
+func redactValue(redactors []Redactor, key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			out[k] = redactValue(redactors, k, vv)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, vv := range v {
+			out[i] = redactValue(redactors, key, vv)
+		}
+		return out
+	default:
+		for _, r := range redactors {
+			if redacted, changed := r.Redact(key, v); changed {
+				v = redacted
+			}
+		}
+		return v
+	}
+}
@@ -0,0 +1,69 @@
+package logrusgce
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	fieldTraceID      = "trace_id"
+	fieldSpanID       = "span_id"
+	fieldTraceSampled = "trace_sampled"
+)
+
+// extractTrace looks for Cloud Trace correlation data on the entry, first in
+// the well-known logrus fields (trace_id, span_id, trace_sampled) and falling
+// back to an OpenTelemetry SpanContext carried on entryCtx. Matched source
+// fields are deleted from data so they aren't logged twice. traceID is
+// already expanded to the "projects/<projectID>/traces/<traceID>" form
+// expected by Cloud Logging when projectID is non-empty.
+func extractTrace(projectID string, data logrus.Fields, entryCtx context.Context) (traceID string, spanID string, sampled bool, ok bool) {
+	// sawField tracks whether any of the three well-known fields were
+	// present and well-typed, independently of ok/traceID/spanID, so that
+	// an entry carrying trace_sampled alone (no trace_id/span_id) still
+	// reports it instead of falling through to the context lookup below.
+	sawField := false
+	if v, present := data[fieldTraceID]; present {
+		if s, isStr := v.(string); isStr {
+			traceID = s
+			sawField = true
+		}
+		delete(data, fieldTraceID)
+	}
+	if v, present := data[fieldSpanID]; present {
+		if s, isStr := v.(string); isStr {
+			spanID = s
+			sawField = true
+		}
+		delete(data, fieldSpanID)
+	}
+	if v, present := data[fieldTraceSampled]; present {
+		if b, isBool := v.(bool); isBool {
+			sampled = b
+			sawField = true
+		}
+		delete(data, fieldTraceSampled)
+	}
+	if sawField {
+		return withProject(projectID, traceID), spanID, sampled, true
+	}
+
+	if entryCtx == nil {
+		return "", "", false, false
+	}
+	sc := trace.SpanContextFromContext(entryCtx)
+	if !sc.IsValid() {
+		return "", "", false, false
+	}
+	return withProject(projectID, sc.TraceID().String()), sc.SpanID().String(), sc.IsSampled(), true
+}
+
+func withProject(projectID, traceID string) string {
+	if traceID == "" || projectID == "" {
+		return traceID
+	}
+	return fmt.Sprintf("projects/%s/traces/%s", projectID, traceID)
+}
@@ -0,0 +1,106 @@
+package logrusgce
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExtractTrace(t *testing.T) {
+	tests := []struct {
+		name          string
+		projectID     string
+		data          logrus.Fields
+		ctx           context.Context
+		wantTraceID   string
+		wantSpanID    string
+		wantSampled   bool
+		wantOK        bool
+		wantDataAfter logrus.Fields
+	}{
+		{
+			name:          "trace_id and span_id are expanded with the project",
+			projectID:     "my-project",
+			data:          logrus.Fields{"trace_id": "abc123", "span_id": "def456"},
+			wantTraceID:   "projects/my-project/traces/abc123",
+			wantSpanID:    "def456",
+			wantOK:        true,
+			wantDataAfter: logrus.Fields{},
+		},
+		{
+			name:          "trace_sampled alone is still reported",
+			data:          logrus.Fields{"trace_sampled": true},
+			wantSampled:   true,
+			wantOK:        true,
+			wantDataAfter: logrus.Fields{},
+		},
+		{
+			name:          "no well-known fields and no context falls through to not-ok",
+			data:          logrus.Fields{},
+			wantOK:        false,
+			wantDataAfter: logrus.Fields{},
+		},
+		{
+			name:          "no well-known fields, invalid context falls through to not-ok",
+			data:          logrus.Fields{},
+			ctx:           context.Background(),
+			wantOK:        false,
+			wantDataAfter: logrus.Fields{},
+		},
+		{
+			name:          "without a projectID the trace id is left bare",
+			data:          logrus.Fields{"trace_id": "abc123"},
+			wantTraceID:   "abc123",
+			wantOK:        true,
+			wantDataAfter: logrus.Fields{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, spanID, sampled, ok := extractTrace(tt.projectID, tt.data, tt.ctx)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if traceID != tt.wantTraceID {
+				t.Fatalf("traceID = %q, want %q", traceID, tt.wantTraceID)
+			}
+			if spanID != tt.wantSpanID {
+				t.Fatalf("spanID = %q, want %q", spanID, tt.wantSpanID)
+			}
+			if sampled != tt.wantSampled {
+				t.Fatalf("sampled = %v, want %v", sampled, tt.wantSampled)
+			}
+			if len(tt.data) != len(tt.wantDataAfter) {
+				t.Fatalf("data after extraction = %v, want the well-known fields removed", tt.data)
+			}
+		})
+	}
+}
+
+func TestExtractTraceFallsBackToOpenTelemetrySpanContext(t *testing.T) {
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	spanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	gotTraceID, gotSpanID, gotSampled, ok := extractTrace("my-project", logrus.Fields{}, ctx)
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if want := "projects/my-project/traces/" + traceID.String(); gotTraceID != want {
+		t.Fatalf("traceID = %q, want %q", gotTraceID, want)
+	}
+	if gotSpanID != spanID.String() {
+		t.Fatalf("spanID = %q, want %q", gotSpanID, spanID.String())
+	}
+	if !gotSampled {
+		t.Fatal("sampled = false, want true")
+	}
+}